@@ -8,9 +8,11 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/common/log"
 )
 
 // For Prometheus metrics.
@@ -18,8 +20,11 @@ const namespace = "apache"
 
 // Exporter holds metrics for a single target.
 type Exporter struct {
-	Context context.Context
-	URI     string
+	Context       context.Context
+	Target        *Target
+	client        *http.Client
+	logger        log.Logger
+	subCollectors []SubCollector
 
 	up             *prometheus.Desc
 	scrapeFailures prometheus.Counter
@@ -34,57 +39,80 @@ type Exporter struct {
 	sync.Mutex // To protect metrics from concurrent collects.
 }
 
-// NewExporter returns a new exporter for the given target uri.
-func NewExporter(ctx context.Context, uri string) *Exporter {
+// NewExporter returns a new exporter for the given target.
+func NewExporter(ctx context.Context, target *Target, logger log.Logger) (*Exporter, error) {
+	client, err := target.httpClient()
+	if err != nil {
+		return nil, fmt.Errorf("building HTTP client for target %q: %v", target.ScrapeURI, err)
+	}
+
+	// Targets loaded from a config file carry the target as a const label,
+	// so that MultiExporter can merge several targets' metrics into one
+	// /metrics response without the registry seeing duplicate series.
+	// Ad-hoc/legacy targets (the pre-config.file `?target=<url>` usage)
+	// stay label-free to keep their metrics unchanged.
+	constLabels := prometheus.Labels{}
+	if target.FromConfig {
+		constLabels["target"] = target.Name
+	}
+
 	return &Exporter{
-		Context: ctx,
-		URI:     uri,
+		Context:       ctx,
+		Target:        target,
+		client:        client,
+		logger:        logger,
+		subCollectors: buildSubCollectors(target),
 		up: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "up"),
 			"Could the apache server be reached",
 			nil,
-			nil),
+			constLabels),
 		scrapeFailures: prometheus.NewCounter(prometheus.CounterOpts{
-			Namespace: namespace,
-			Name:      "exporter_scrape_failures_total",
-			Help:      "Number of errors while scraping apache.",
+			Namespace:   namespace,
+			Name:        "exporter_scrape_failures_total",
+			Help:        "Number of errors while scraping apache.",
+			ConstLabels: constLabels,
 		}),
 		accessesTotal: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "accesses_total"),
 			"Current total apache accesses (*)",
 			nil,
-			nil),
+			constLabels),
 		bytesTotal: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "sent_bytes_total"),
 			"Current total bytes sent (*)",
 			nil,
-			nil),
+			constLabels),
 		cpuload: prometheus.NewGauge(prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "cpuload",
-			Help:      "The current percentage CPU used by each worker and in total by all workers combined (*)",
+			Namespace:   namespace,
+			Name:        "cpuload",
+			Help:        "The current percentage CPU used by each worker and in total by all workers combined (*)",
+			ConstLabels: constLabels,
 		}),
 		uptime: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "uptime_seconds_total"),
 			"Current uptime in seconds (*)",
 			nil,
-			nil),
+			constLabels),
 		workers: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "workers",
-			Help:      "Apache worker statuses",
+			Namespace:   namespace,
+			Name:        "workers",
+			Help:        "Apache worker statuses",
+			ConstLabels: constLabels,
 		}, []string{"state"}),
 		scoreboard: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "scoreboard",
-			Help:      "Apache scoreboard statuses",
+			Namespace:   namespace,
+			Name:        "scoreboard",
+			Help:        "Apache scoreboard statuses",
+			ConstLabels: constLabels,
 		}, []string{"state"}),
 		connections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Namespace: namespace,
-			Name:      "connections",
-			Help:      "Apache connection statuses",
+			Namespace:   namespace,
+			Name:        "connections",
+			Help:        "Apache connection statuses",
+			ConstLabels: constLabels,
 		}, []string{"state"}),
-	}
+	}, nil
 }
 
 // Describe implements the prometheus.Collector interface
@@ -98,6 +126,9 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	e.workers.Describe(ch)
 	e.scoreboard.Describe(ch)
 	e.connections.Describe(ch)
+	for _, sub := range e.subCollectors {
+		sub.Describe(ch)
+	}
 }
 
 // splitkv splits colon separated string into two fields
@@ -143,13 +174,13 @@ func (e *Exporter) updateScoreboard(scoreboard string) {
 }
 
 func (e *Exporter) collect(ch chan<- prometheus.Metric) error {
-	req, err := http.NewRequest("GET", e.URI, nil)
+	req, err := e.Target.newRequest()
 	if err != nil {
 		return err
 	}
 	req = req.WithContext(e.Context)
 
-	resp, err := client.Do(req)
+	resp, err := e.client.Do(req)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(e.up, prometheus.GaugeValue, 0)
 		return fmt.Errorf("Error scraping apache: %v", err)
@@ -249,17 +280,45 @@ func (e *Exporter) collect(ch chan<- prometheus.Metric) error {
 		e.connections.Collect(ch)
 	}
 
+	for _, sub := range e.subCollectors {
+		metrics, err := sub.Collect(e.Context, e.client)
+		if err != nil {
+			level.Error(e.logger).Log("msg", "error scraping sub-collector", "target", e.Target.ScrapeURI, "err", err)
+			continue
+		}
+		for _, m := range metrics {
+			ch <- m
+		}
+	}
+
 	return nil
 }
 
 // Collect implements the prometheus.Collector interface
 func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.scrape(ch)
+}
+
+// scrape performs one locked collection pass, logs the outcome with
+// structured fields, and reports whether it succeeded so callers (like
+// MultiExporter) can surface per-target success/duration metrics of their
+// own.
+func (e *Exporter) scrape(ch chan<- prometheus.Metric) error {
+	start := time.Now()
+
 	e.Lock()
-	if err := e.collect(ch); err != nil {
-		log.Errorf("Error scraping target '%s': %s", e.URI, err)
+	err := e.collect(ch)
+	e.Unlock()
+
+	duration := time.Since(start).Seconds()
+
+	if err != nil {
 		e.scrapeFailures.Inc()
 		e.scrapeFailures.Collect(ch)
+		level.Error(e.logger).Log("msg", "scrape failed", "target", e.Target.ScrapeURI, "status", "error", "duration_seconds", duration, "err", err)
+	} else {
+		level.Debug(e.logger).Log("msg", "scrape completed", "target", e.Target.ScrapeURI, "status", "success", "duration_seconds", duration)
 	}
-	e.Unlock()
-	return
+
+	return err
 }
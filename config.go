@@ -0,0 +1,202 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Config describes the exporter's `--config.file`: a set of named Apache
+// targets, each with its own connection details.
+type Config struct {
+	Targets map[string]*Target `yaml:"targets"`
+}
+
+// BasicAuth holds HTTP basic auth credentials for a target.
+type BasicAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// TLSConfig describes the TLS settings used when connecting to a target.
+type TLSConfig struct {
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// Target describes a single Apache instance to scrape.
+type Target struct {
+	// Name identifies this target in the "target" label attached to every
+	// metric it produces when FromConfig is set, so multiple targets can be
+	// merged into one /metrics response without colliding. It's the
+	// target's key in Config.Targets, or its scrape URI for ad-hoc
+	// `?target=<url>` requests.
+	Name string `yaml:"-"`
+
+	// FromConfig marks targets loaded from a --config.file, as opposed to
+	// ad-hoc/legacy `?target=<url>` requests. Only these targets get the
+	// "target" const label, so pre-existing single-target scraping keeps
+	// producing its original, label-free metrics.
+	FromConfig bool `yaml:"-"`
+
+	ScrapeURI     string            `yaml:"scrape_uri"`
+	BasicAuth     *BasicAuth        `yaml:"basic_auth"`
+	BearerToken   string            `yaml:"bearer_token"`
+	TLSConfig     *TLSConfig        `yaml:"tls_config"`
+	CustomHeaders map[string]string `yaml:"custom_headers"`
+	HostOverride  string            `yaml:"host_override"`
+	ScrapeTimeout time.Duration     `yaml:"scrape_timeout"`
+
+	// Sub-collectors for auxiliary status endpoints, scraped alongside
+	// mod_status and merged into this target's output. Each is only
+	// enabled when configured.
+	ModQoS      *ModQoSConfig      `yaml:"mod_qos"`
+	ModSecurity *ModSecurityConfig `yaml:"mod_security"`
+	PHPFPM      *PHPFPMConfig      `yaml:"php_fpm"`
+
+	// clientOnce/client/clientErr memoize httpClient() so the target's
+	// *http.Client (and its TLS setup) is built once and reused across
+	// scrapes instead of on every request.
+	clientOnce sync.Once
+	client     *http.Client
+	clientErr  error
+}
+
+// ModQoSConfig points at mod_qos's connection/vhost status page.
+type ModQoSConfig struct {
+	URI string `yaml:"uri"`
+}
+
+// ModSecurityConfig points at a status endpoint exposing mod_security
+// rule-hit counters.
+type ModSecurityConfig struct {
+	URI string `yaml:"uri"`
+}
+
+// PHPFPMConfig points at a PHP-FPM pool's `?full&json` status page,
+// typically reached through mod_proxy_fcgi.
+type PHPFPMConfig struct {
+	URI string `yaml:"uri"`
+}
+
+// LoadConfig reads and parses a YAML config file describing the exporter's
+// targets.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %v", err)
+	}
+
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing config file: %v", err)
+	}
+
+	for name, t := range cfg.Targets {
+		if t.ScrapeURI == "" {
+			return nil, fmt.Errorf("target %q: scrape_uri is required", name)
+		}
+		t.Name = name
+		t.FromConfig = true
+	}
+
+	return cfg, nil
+}
+
+// NewTarget builds a Target from a bare scrape URI, using the exporter's
+// global defaults. It is used for backward-compatible `?target=<url>`
+// requests that don't reference a named config entry.
+func NewTarget(uri string) *Target {
+	return &Target{
+		Name:      uri,
+		ScrapeURI: uri,
+		TLSConfig: &TLSConfig{
+			InsecureSkipVerify: insecure,
+		},
+	}
+}
+
+// httpClient returns the *http.Client this target should scrape with,
+// building it from the target's TLS settings and scrape timeout on first
+// use and reusing it (and its connection pool) on every subsequent call.
+func (t *Target) httpClient() (*http.Client, error) {
+	t.clientOnce.Do(func() {
+		t.client, t.clientErr = t.buildHTTPClient()
+	})
+	return t.client, t.clientErr
+}
+
+// buildHTTPClient constructs the *http.Client this target should scrape
+// with, applying its TLS settings and scrape timeout.
+func (t *Target) buildHTTPClient() (*http.Client, error) {
+	tlsConfig := &tls.Config{}
+
+	if t.TLSConfig != nil {
+		tlsConfig.InsecureSkipVerify = t.TLSConfig.InsecureSkipVerify
+
+		if t.TLSConfig.CAFile != "" {
+			ca, err := ioutil.ReadFile(t.TLSConfig.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading ca_file: %v", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("ca_file %q contains no valid certificates", t.TLSConfig.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+
+		if t.TLSConfig.CertFile != "" || t.TLSConfig.KeyFile != "" {
+			cert, err := tls.LoadX509KeyPair(t.TLSConfig.CertFile, t.TLSConfig.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("loading client keypair: %v", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	timeout := t.ScrapeTimeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
+
+// newRequest builds the scrape HTTP request for this target, applying auth,
+// custom headers and the host override.
+func (t *Target) newRequest() (*http.Request, error) {
+	req, err := http.NewRequest("GET", t.ScrapeURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.BasicAuth != nil {
+		req.SetBasicAuth(t.BasicAuth.Username, t.BasicAuth.Password)
+	}
+	if t.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+t.BearerToken)
+	}
+	for k, v := range t.CustomHeaders {
+		req.Header.Set(k, v)
+	}
+	if t.HostOverride != "" {
+		req.Host = t.HostOverride
+	}
+
+	return req, nil
+}
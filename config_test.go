@@ -0,0 +1,122 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTempFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+	return path
+}
+
+func TestLoadConfigScrapeURIValidation(t *testing.T) {
+	dir := t.TempDir()
+
+	cases := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{
+			name:    "valid target",
+			yaml:    "targets:\n  local:\n    scrape_uri: http://localhost/server-status?auto\n",
+			wantErr: false,
+		},
+		{
+			name:    "missing scrape_uri",
+			yaml:    "targets:\n  local:\n    host_override: example.org\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty scrape_uri",
+			yaml:    "targets:\n  local:\n    scrape_uri: \"\"\n",
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := writeTempFile(t, dir, c.name+".yml", c.yaml)
+			_, err := LoadConfig(path)
+			if (err != nil) != c.wantErr {
+				t.Errorf("LoadConfig(%q): err = %v, wantErr %v", c.name, err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	if _, err := LoadConfig("/nonexistent/apache_exporter.yml"); err == nil {
+		t.Error("LoadConfig with a missing file: got nil error, want one")
+	}
+}
+
+func TestBuildHTTPClientTLSErrors(t *testing.T) {
+	dir := t.TempDir()
+	badCAFile := writeTempFile(t, dir, "bad_ca.pem", "not a certificate")
+	certFile := writeTempFile(t, dir, "cert.pem", "not a certificate")
+	keyFile := writeTempFile(t, dir, "key.pem", "not a key")
+
+	cases := []struct {
+		name    string
+		target  *Target
+		wantErr bool
+	}{
+		{
+			name:    "no TLS config",
+			target:  &Target{ScrapeURI: "http://localhost/server-status?auto"},
+			wantErr: false,
+		},
+		{
+			name: "nonexistent ca_file",
+			target: &Target{
+				ScrapeURI: "https://localhost/server-status?auto",
+				TLSConfig: &TLSConfig{CAFile: "/nonexistent/ca.pem"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid ca_file contents",
+			target: &Target{
+				ScrapeURI: "https://localhost/server-status?auto",
+				TLSConfig: &TLSConfig{CAFile: badCAFile},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid cert/key contents",
+			target: &Target{
+				ScrapeURI: "https://localhost/server-status?auto",
+				TLSConfig: &TLSConfig{CertFile: certFile, KeyFile: keyFile},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := c.target.buildHTTPClient()
+			if (err != nil) != c.wantErr {
+				t.Errorf("buildHTTPClient(%q): err = %v, wantErr %v", c.name, err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildHTTPClientDefaultTimeout(t *testing.T) {
+	target := &Target{ScrapeURI: "http://localhost/server-status?auto"}
+	client, err := target.buildHTTPClient()
+	if err != nil {
+		t.Fatalf("buildHTTPClient: %v", err)
+	}
+	if client.Timeout != 5*time.Second {
+		t.Errorf("default timeout = %v, want %v", client.Timeout, 5*time.Second)
+	}
+}
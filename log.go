@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// newLogger builds the exporter's root logger from the --log.level and
+// --log.format flags.
+func newLogger(logLevel, logFormat string) log.Logger {
+	var logger log.Logger
+	if logFormat == "json" {
+		logger = log.NewJSONLogger(log.NewSyncWriter(os.Stderr))
+	} else {
+		logger = log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
+	}
+	logger = log.With(logger, "ts", log.DefaultTimestampUTC, "caller", log.DefaultCaller)
+
+	var lvl level.Option
+	switch logLevel {
+	case "debug":
+		lvl = level.AllowDebug()
+	case "warn":
+		lvl = level.AllowWarn()
+	case "error":
+		lvl = level.AllowError()
+	default:
+		lvl = level.AllowInfo()
+	}
+
+	return level.NewFilter(logger, lvl)
+}
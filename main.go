@@ -1,16 +1,16 @@
 package main
 
 import (
-	"crypto/tls"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
 
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/prometheus/common/log"
 	"github.com/prometheus/common/version"
+	"github.com/prometheus/exporter-toolkit/web"
 )
 
 var (
@@ -19,32 +19,45 @@ var (
 	metricsEndpoint  = "/metrics"
 	insecure         = false
 	showVersion      = false
+	configFile       = ""
+	webConfigFile    = ""
+	logLevel         = "info"
+	logFormat        = "logfmt"
 
 	defaultTarget = "http://localhost/server-status?auto"
-	client        *http.Client
+	config        *Config
+	logger        = newLogger(logLevel, logFormat)
 )
 
 func main() {
 	flag.StringVar(&listeningAddress, "telemetry.address", listeningAddress, "Address on which to expose metrics")
 	flag.BoolVar(&insecure, "insecure", insecure, "Ignore server certificate if using https")
 	flag.BoolVar(&showVersion, "version", showVersion, "Print version information")
+	flag.StringVar(&configFile, "config.file", configFile, "Path to a YAML file describing named Apache targets")
+	flag.StringVar(&webConfigFile, "web.config.file", webConfigFile, "Path to a YAML file with TLS and basic auth options for the exporter's own HTTP server")
+	flag.StringVar(&logLevel, "log.level", logLevel, "Only log messages with the given severity or above (debug, info, warn, error)")
+	flag.StringVar(&logFormat, "log.format", logFormat, "Output format of log messages (logfmt, json)")
 	flag.Parse()
 
+	logger = newLogger(logLevel, logFormat)
+
 	if showVersion {
 		fmt.Println(version.Print("apache_exporter"))
 		os.Exit(0)
 	}
 
-	client = &http.Client{
-		Transport: &http.Transport{
-			Proxy:           http.ProxyFromEnvironment,
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		},
+	if configFile != "" {
+		var err error
+		config, err = LoadConfig(configFile)
+		if err != nil {
+			level.Error(logger).Log("msg", "error loading config file", "file", configFile, "err", err)
+			os.Exit(1)
+		}
 	}
 
-	log.Infoln("Starting apache_exporter", version.Info())
-	log.Infoln("Build context", version.BuildContext())
-	log.Infof("Starting Server: %s", listeningAddress)
+	level.Info(logger).Log("msg", "Starting apache_exporter", "version", version.Info())
+	level.Info(logger).Log("msg", "Build context", "context", version.BuildContext())
+	level.Info(logger).Log("msg", "Starting server", "address", listeningAddress)
 
 	http.HandleFunc(metricsEndpoint, func(w http.ResponseWriter, r *http.Request) {
 		reg := prometheus.NewRegistry()
@@ -55,11 +68,24 @@ func main() {
 			reg.MustRegister(prometheus.NewGoCollector())
 		}
 
-		if target := r.FormValue("target"); target != "false" {
-			if target == "" {
-				target = defaultTarget
+		name := r.FormValue("target")
+		if name == "" && config != nil {
+			// No explicit target and a multi-target config is loaded:
+			// scrape every configured target in one response.
+			reg.MustRegister(NewMultiExporter(r.Context(), config, logger))
+		} else if name != "false" {
+			target, err := resolveTarget(name)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			exporter, err := NewExporter(r.Context(), target, logger)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
 			}
-			reg.MustRegister(NewExporter(target))
+			reg.MustRegister(exporter)
 		}
 
 		h := promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
@@ -69,7 +95,34 @@ func main() {
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, landingPage)
 	})
-	log.Fatal(http.ListenAndServe(listeningAddress, nil))
+
+	srv := &http.Server{}
+	webFlags := &web.FlagConfig{
+		WebListenAddresses: &[]string{listeningAddress},
+		WebConfigFile:      &webConfigFile,
+	}
+	if err := web.ListenAndServe(srv, webFlags, logger); err != nil {
+		level.Error(logger).Log("msg", "server stopped", "err", err)
+		os.Exit(1)
+	}
+}
+
+// resolveTarget looks up name in the configured targets. If name isn't a
+// known target, it's treated as a raw scrape URL for backward compatibility
+// with the pre-config.file `?target=<url>` usage. An empty name falls back
+// to defaultTarget.
+func resolveTarget(name string) (*Target, error) {
+	if name == "" {
+		name = defaultTarget
+	}
+
+	if config != nil {
+		if target, ok := config.Targets[name]; ok {
+			return target, nil
+		}
+	}
+
+	return NewTarget(name), nil
 }
 
 const landingPage = `<!doctype html><html>
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// modQoSCollector scrapes mod_qos's `/qos-viewer?auto` endpoint for global
+// connection counters and per-vhost limits.
+type modQoSCollector struct {
+	cfg *ModQoSConfig
+
+	connections *prometheus.Desc
+	vhostLimit  *prometheus.Desc
+}
+
+func newModQoSCollector(cfg *ModQoSConfig, constLabels prometheus.Labels) *modQoSCollector {
+	return &modQoSCollector{
+		cfg: cfg,
+		connections: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "qos", "connections"),
+			"Connections currently tracked by mod_qos (*)",
+			[]string{"state"},
+			constLabels),
+		vhostLimit: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "qos", "vhost_limit"),
+			"mod_qos per-vhost connection limit and current usage (*)",
+			[]string{"vhost", "state"},
+			constLabels),
+	}
+}
+
+// Describe implements the SubCollector interface
+func (c *modQoSCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.connections
+	ch <- c.vhostLimit
+}
+
+// Collect implements the SubCollector interface. It expects lines of the
+// form "conn_current: N", "conn_limit: N" for the global counters, and
+// "vhost=<name> current=<n> limit=<n>" for per-vhost limits.
+func (c *modQoSCollector) Collect(ctx context.Context, client *http.Client) ([]prometheus.Metric, error) {
+	req, err := http.NewRequest("GET", c.cfg.URI, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error scraping mod_qos: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("mod_qos status %s (%d): %s", resp.Status, resp.StatusCode, data)
+	}
+
+	var metrics []prometheus.Metric
+
+	for _, l := range strings.Split(string(data), "\n") {
+		switch key, v := splitkv(l); key {
+		case "conn_current":
+			if val, err := strconv.ParseFloat(v, 64); err == nil {
+				metrics = append(metrics, prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, val, "current"))
+			}
+		case "conn_limit":
+			if val, err := strconv.ParseFloat(v, 64); err == nil {
+				metrics = append(metrics, prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, val, "limit"))
+			}
+		default:
+			if vhost, current, limit, ok := parseQoSVhostLine(l); ok {
+				metrics = append(metrics,
+					prometheus.MustNewConstMetric(c.vhostLimit, prometheus.GaugeValue, current, vhost, "current"),
+					prometheus.MustNewConstMetric(c.vhostLimit, prometheus.GaugeValue, limit, vhost, "limit"))
+			}
+		}
+	}
+
+	return metrics, nil
+}
+
+// parseQoSVhostLine parses a "vhost=<name> current=<n> limit=<n>" line.
+func parseQoSVhostLine(line string) (vhost string, current, limit float64, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return "", 0, 0, false
+	}
+
+	values := map[string]string{}
+	for _, f := range fields {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 {
+			return "", 0, 0, false
+		}
+		values[kv[0]] = kv[1]
+	}
+
+	vhost, hasVhost := values["vhost"]
+	currentStr, hasCurrent := values["current"]
+	limitStr, hasLimit := values["limit"]
+	if !hasVhost || !hasCurrent || !hasLimit {
+		return "", 0, 0, false
+	}
+
+	current, err := strconv.ParseFloat(currentStr, 64)
+	if err != nil {
+		return "", 0, 0, false
+	}
+	limit, err = strconv.ParseFloat(limitStr, 64)
+	if err != nil {
+		return "", 0, 0, false
+	}
+
+	return vhost, current, limit, true
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// modSecurityCollector scrapes a mod_security status endpoint exposing
+// per-rule hit counters, one "id:hits" pair per line, e.g. "950001:42".
+type modSecurityCollector struct {
+	cfg *ModSecurityConfig
+
+	ruleHitsTotal *prometheus.Desc
+}
+
+func newModSecurityCollector(cfg *ModSecurityConfig, constLabels prometheus.Labels) *modSecurityCollector {
+	return &modSecurityCollector{
+		cfg: cfg,
+		ruleHitsTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "modsecurity", "rule_hits_total"),
+			"Number of times a mod_security rule has matched",
+			[]string{"rule_id"},
+			constLabels),
+	}
+}
+
+// Describe implements the SubCollector interface
+func (c *modSecurityCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.ruleHitsTotal
+}
+
+// Collect implements the SubCollector interface
+func (c *modSecurityCollector) Collect(ctx context.Context, client *http.Client) ([]prometheus.Metric, error) {
+	req, err := http.NewRequest("GET", c.cfg.URI, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error scraping mod_security: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("mod_security status %s (%d): %s", resp.Status, resp.StatusCode, data)
+	}
+
+	var metrics []prometheus.Metric
+
+	for _, l := range strings.Split(string(data), "\n") {
+		ruleID, hits, ok := parseModSecurityLine(l)
+		if !ok {
+			continue
+		}
+
+		metrics = append(metrics, prometheus.MustNewConstMetric(c.ruleHitsTotal, prometheus.CounterValue, hits, ruleID))
+	}
+
+	return metrics, nil
+}
+
+// parseModSecurityLine parses a "<rule_id>:<hits>" line, e.g. "950001:42".
+func parseModSecurityLine(line string) (ruleID string, hits float64, ok bool) {
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 {
+		return "", 0, false
+	}
+
+	ruleID = strings.TrimSpace(parts[0])
+	hits, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || ruleID == "" {
+		return "", 0, false
+	}
+
+	return ruleID, hits, true
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestParseModSecurityLine(t *testing.T) {
+	cases := []struct {
+		line      string
+		ruleID    string
+		hits      float64
+		wantMatch bool
+	}{
+		{"950001:42", "950001", 42, true},
+		{"950001: 42", "950001", 42, true},
+		{"", "", 0, false},
+		{"950001", "", 0, false},
+		{"950001:not-a-number", "", 0, false},
+	}
+
+	for _, c := range cases {
+		ruleID, hits, ok := parseModSecurityLine(c.line)
+		if ok != c.wantMatch {
+			t.Errorf("parseModSecurityLine(%q): ok = %v, want %v", c.line, ok, c.wantMatch)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if ruleID != c.ruleID || hits != c.hits {
+			t.Errorf("parseModSecurityLine(%q) = (%q, %v), want (%q, %v)", c.line, ruleID, hits, c.ruleID, c.hits)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultScrapeConcurrency bounds how many targets MultiExporter scrapes at
+// once, so a config with hundreds of targets doesn't open hundreds of
+// sockets to Apache in one go.
+const defaultScrapeConcurrency = 10
+
+// MultiExporter fans out to every target in Config in parallel and merges
+// the results into a single Prometheus response, so one Prometheus job can
+// scrape N Apache instances without N round-trips to this exporter.
+type MultiExporter struct {
+	Context     context.Context
+	Config      *Config
+	Concurrency int
+	logger      log.Logger
+
+	scrapeDuration *prometheus.Desc
+	scrapeSuccess  *prometheus.Desc
+}
+
+// NewMultiExporter returns an exporter that scrapes every target in cfg.
+func NewMultiExporter(ctx context.Context, cfg *Config, logger log.Logger) *MultiExporter {
+	return &MultiExporter{
+		Context:     ctx,
+		Config:      cfg,
+		Concurrency: defaultScrapeConcurrency,
+		logger:      logger,
+		scrapeDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "scrape", "collector_duration_seconds"),
+			"Time it took to scrape a target",
+			[]string{"target"},
+			nil),
+		scrapeSuccess: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "scrape", "collector_success"),
+			"Whether scraping a target succeeded",
+			[]string{"target"},
+			nil),
+	}
+}
+
+// Describe implements the prometheus.Collector interface
+func (m *MultiExporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.scrapeDuration
+	ch <- m.scrapeSuccess
+}
+
+// Collect implements the prometheus.Collector interface
+func (m *MultiExporter) Collect(ch chan<- prometheus.Metric) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, m.Concurrency)
+
+	for name, target := range m.Config.Targets {
+		wg.Add(1)
+		go func(name string, target *Target) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			m.collectTarget(ch, name, target)
+		}(name, target)
+	}
+
+	wg.Wait()
+}
+
+func (m *MultiExporter) collectTarget(ch chan<- prometheus.Metric, name string, target *Target) {
+	start := time.Now()
+
+	exporter, err := NewExporter(m.Context, target, m.logger)
+	if err != nil {
+		level.Error(m.logger).Log("msg", "error building exporter", "target", name, "err", err)
+	} else {
+		err = exporter.scrape(ch)
+	}
+
+	duration := time.Since(start).Seconds()
+	success := 1.0
+	if err != nil {
+		success = 0
+	}
+
+	ch <- prometheus.MustNewConstMetric(m.scrapeDuration, prometheus.GaugeValue, duration, name)
+	ch <- prometheus.MustNewConstMetric(m.scrapeSuccess, prometheus.GaugeValue, success, name)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SubCollector is a pluggable scraper for an auxiliary status endpoint
+// (mod_qos, mod_security, PHP-FPM, ...) that is scraped alongside a
+// target's mod_status page and merged into the same Exporter output.
+type SubCollector interface {
+	// Describe sends the sub-collector's metric descriptors to ch.
+	Describe(ch chan<- *prometheus.Desc)
+	// Collect scrapes the sub-collector's endpoint using client and
+	// returns the resulting metrics.
+	Collect(ctx context.Context, client *http.Client) ([]prometheus.Metric, error)
+}
+
+// buildSubCollectors returns the sub-collectors enabled on target.
+func buildSubCollectors(target *Target) []SubCollector {
+	var subs []SubCollector
+
+	// Match the const labels the target's own metrics use: only targets
+	// loaded from a config file carry the "target" label, since
+	// sub-collectors are themselves only reachable through config.file
+	// target definitions.
+	constLabels := prometheus.Labels{}
+	if target.FromConfig {
+		constLabels["target"] = target.Name
+	}
+
+	if target.ModQoS != nil {
+		subs = append(subs, newModQoSCollector(target.ModQoS, constLabels))
+	}
+	if target.ModSecurity != nil {
+		subs = append(subs, newModSecurityCollector(target.ModSecurity, constLabels))
+	}
+	if target.PHPFPM != nil {
+		subs = append(subs, newPHPFPMCollector(target.PHPFPM, constLabels))
+	}
+
+	return subs
+}